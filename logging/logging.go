@@ -0,0 +1,293 @@
+// Package logging provides the analyzer's own leveled, structured logger:
+// JSON records with an optional colorized stderr mirror, size/time-based
+// rotation with gzip of rolled files, and an optional syslog sink.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a logging severity, ordered from most to least verbose.
+type Level int
+
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelTrace:
+		return "trace"
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// ansiColor returns the color escape code used when mirroring to a TTY.
+func (l Level) ansiColor() string {
+	switch l {
+	case LevelTrace, LevelDebug:
+		return "\x1b[90m" // gray
+	case LevelInfo:
+		return "\x1b[36m" // cyan
+	case LevelWarn:
+		return "\x1b[33m" // yellow
+	case LevelError, LevelFatal:
+		return "\x1b[31m" // red
+	default:
+		return ""
+	}
+}
+
+const ansiReset = "\x1b[0m"
+
+// ParseLevel parses a case-insensitive level name, defaulting to Info for an
+// empty string.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "", "info":
+		return LevelInfo, nil
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return LevelDebug, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	default:
+		return LevelInfo, fmt.Errorf("logging: unknown level %q", name)
+	}
+}
+
+// record is the structured JSON shape every log line is emitted as.
+type record struct {
+	Time   time.Time              `json:"timestamp"`
+	Level  string                 `json:"level"`
+	Msg    string                 `json:"msg"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Config controls how a Logger writes.
+type Config struct {
+	// Level is the minimum level that will be emitted.
+	Level string
+
+	// FilePath is the primary log file; empty disables file output.
+	FilePath string
+	// MaxSizeMB rotates FilePath once it reaches this size. 0 disables
+	// size-based rotation.
+	MaxSizeMB int
+	// RotateInterval rotates FilePath on a fixed cadence (e.g. 24h). Zero
+	// disables time-based rotation.
+	RotateInterval time.Duration
+	// Compress gzips rotated files.
+	Compress bool
+
+	// Stderr mirrors records to stderr, colorized when it's a TTY.
+	Stderr bool
+
+	// Syslog, when non-empty, is a "network:addr" (e.g. "udp:localhost:514")
+	// to forward records to; "local" dials the local syslog daemon.
+	Syslog string
+}
+
+// Logger is a leveled, structured logger writing JSON records to a rotating
+// file, optionally mirrored to stderr and/or syslog.
+type Logger struct {
+	level Level
+
+	mu      sync.Mutex
+	file    io.WriteCloser
+	stderr  bool
+	isTTY   bool
+	syslogW *syslog.Writer
+	fields  map[string]interface{}
+}
+
+// New builds a Logger from cfg.
+func New(cfg Config) (*Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Logger{level: level, stderr: cfg.Stderr}
+
+	if cfg.FilePath != "" {
+		rw, err := newRotateWriter(cfg.FilePath, cfg.MaxSizeMB, cfg.RotateInterval, cfg.Compress)
+		if err != nil {
+			return nil, fmt.Errorf("logging: opening %s: %w", cfg.FilePath, err)
+		}
+		l.file = rw
+	}
+
+	if cfg.Stderr {
+		if info, err := os.Stderr.Stat(); err == nil {
+			l.isTTY = (info.Mode() & os.ModeCharDevice) != 0
+		}
+	}
+
+	if cfg.Syslog != "" {
+		network, addr := splitSyslogTarget(cfg.Syslog)
+		var w *syslog.Writer
+		var err error
+		if network == "local" {
+			w, err = syslog.New(syslog.LOG_INFO, "log-analyzer")
+		} else {
+			w, err = syslog.Dial(network, addr, syslog.LOG_INFO, "log-analyzer")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("logging: dialing syslog: %w", err)
+		}
+		l.syslogW = w
+	}
+
+	return l, nil
+}
+
+func splitSyslogTarget(target string) (network, addr string) {
+	for i := 0; i < len(target); i++ {
+		if target[i] == ':' {
+			return target[:i], target[i+1:]
+		}
+	}
+	return "local", ""
+}
+
+// With returns a child Logger that attaches kv (alternating key, value
+// pairs) to every record it emits, in addition to this Logger's own fields.
+func (l *Logger) With(kv ...interface{}) *Logger {
+	fields := make(map[string]interface{}, len(l.fields)+len(kv)/2)
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			continue
+		}
+		fields[key] = kv[i+1]
+	}
+	return &Logger{
+		level:   l.level,
+		file:    l.file,
+		stderr:  l.stderr,
+		isTTY:   l.isTTY,
+		syslogW: l.syslogW,
+		fields:  fields,
+	}
+}
+
+func (l *Logger) log(level Level, msg string, kv ...interface{}) {
+	if level < l.level {
+		return
+	}
+
+	fields := l.fields
+	if len(kv) > 0 {
+		fields = make(map[string]interface{}, len(l.fields)+len(kv)/2)
+		for k, v := range l.fields {
+			fields[k] = v
+		}
+		for i := 0; i+1 < len(kv); i += 2 {
+			key, ok := kv[i].(string)
+			if !ok {
+				continue
+			}
+			fields[key] = kv[i+1]
+		}
+	}
+
+	rec := record{Time: time.Now(), Level: level.String(), Msg: msg, Fields: fields}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		l.file.Write(line)
+	}
+	if l.stderr {
+		if l.isTTY {
+			fmt.Fprintf(os.Stderr, "%s%s%s\n", level.ansiColor(), string(line[:len(line)-1]), ansiReset)
+		} else {
+			os.Stderr.Write(line)
+		}
+	}
+	if l.syslogW != nil {
+		writeSyslog(l.syslogW, level, string(line))
+	}
+}
+
+func writeSyslog(w *syslog.Writer, level Level, line string) {
+	switch level {
+	case LevelTrace, LevelDebug:
+		w.Debug(line)
+	case LevelInfo:
+		w.Info(line)
+	case LevelWarn:
+		w.Warning(line)
+	default:
+		w.Err(line)
+	}
+}
+
+func (l *Logger) Trace(msg string, kv ...interface{}) { l.log(LevelTrace, msg, kv...) }
+func (l *Logger) Debug(msg string, kv ...interface{}) { l.log(LevelDebug, msg, kv...) }
+func (l *Logger) Info(msg string, kv ...interface{})  { l.log(LevelInfo, msg, kv...) }
+func (l *Logger) Warn(msg string, kv ...interface{})  { l.log(LevelWarn, msg, kv...) }
+func (l *Logger) Error(msg string, kv ...interface{}) { l.log(LevelError, msg, kv...) }
+
+// Fatal logs at LevelFatal and terminates the process.
+func (l *Logger) Fatal(msg string, kv ...interface{}) {
+	l.log(LevelFatal, msg, kv...)
+	l.Close()
+	os.Exit(1)
+}
+
+// Close flushes and releases any open file or syslog connection.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var firstErr error
+	if l.file != nil {
+		if err := l.file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if l.syslogW != nil {
+		if err := l.syslogW.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
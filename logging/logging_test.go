@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoggerWithInheritsAndExtendsFields(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	l, err := New(Config{Level: "info", FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := l.With("request_id", "abc")
+	child.Info("handled request", "status", 200)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rec record
+	if err := json.Unmarshal(data[:len(data)-1], &rec); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Fields["request_id"] != "abc" {
+		t.Fatalf("expected inherited field request_id=abc, got %v", rec.Fields["request_id"])
+	}
+	if rec.Fields["status"] != float64(200) {
+		t.Fatalf("expected call-site field status=200, got %v", rec.Fields["status"])
+	}
+}
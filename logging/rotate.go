@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// rotateWriter is an io.WriteCloser that rolls its underlying file once it
+// reaches maxSizeMB or rotateInterval has elapsed, gzip-compressing the
+// rolled file when compress is set.
+type rotateWriter struct {
+	path           string
+	maxSizeBytes   int64
+	rotateInterval time.Duration
+	compress       bool
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+func newRotateWriter(path string, maxSizeMB int, rotateInterval time.Duration, compress bool) (*rotateWriter, error) {
+	rw := &rotateWriter{
+		path:           path,
+		maxSizeBytes:   int64(maxSizeMB) * 1024 * 1024,
+		rotateInterval: rotateInterval,
+		compress:       compress,
+	}
+	if err := rw.open(); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func (rw *rotateWriter) open() error {
+	f, err := os.OpenFile(rw.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rw.file = f
+	rw.size = info.Size()
+	rw.openedAt = time.Now()
+	return nil
+}
+
+func (rw *rotateWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.shouldRotateLocked() {
+		if err := rw.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rw.file.Write(p)
+	rw.size += int64(n)
+	return n, err
+}
+
+func (rw *rotateWriter) shouldRotateLocked() bool {
+	if rw.maxSizeBytes > 0 && rw.size >= rw.maxSizeBytes {
+		return true
+	}
+	if rw.rotateInterval > 0 && time.Since(rw.openedAt) >= rw.rotateInterval {
+		return true
+	}
+	return false
+}
+
+func (rw *rotateWriter) rotateLocked() error {
+	if err := rw.file.Close(); err != nil {
+		return err
+	}
+
+	rolled := fmt.Sprintf("%s.%s", rw.path, time.Now().Format("2006-01-02T15-04-05"))
+	if err := os.Rename(rw.path, rolled); err != nil {
+		return err
+	}
+
+	if rw.compress {
+		go compressAndRemove(rolled)
+	}
+
+	return rw.open()
+}
+
+func compressAndRemove(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		return
+	}
+	if err := gz.Close(); err != nil {
+		return
+	}
+
+	src.Close()
+	os.Remove(path)
+}
+
+func (rw *rotateWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	return rw.file.Close()
+}
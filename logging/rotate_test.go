@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateWriterRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rw, err := newRotateWriter(path, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw.maxSizeBytes = 10
+	defer rw.Close()
+
+	if _, err := rw.Write([]byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rw.Write([]byte("more")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected the active file plus one rolled file, got %d entries", len(entries))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "more" {
+		t.Fatalf("expected the active file to contain only post-rotation writes, got %q", data)
+	}
+}
+
+func TestRotateWriterCompressesRolledFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rw, err := newRotateWriter(path, 0, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw.maxSizeBytes = 5
+	defer rw.Close()
+
+	if _, err := rw.Write([]byte("hello world")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rw.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+
+	var gzPath string
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gzPath = ""
+		rolledStillPresent := false
+		for _, e := range entries {
+			switch {
+			case filepath.Ext(e.Name()) == ".gz":
+				gzPath = filepath.Join(dir, e.Name())
+			case e.Name() != filepath.Base(path):
+				rolledStillPresent = true
+			}
+		}
+		// compressAndRemove only deletes the uncompressed rolled file once
+		// the .gz write has fully completed, so waiting for it to disappear
+		// avoids reading a partially written .gz.
+		if gzPath != "" && !rolledStillPresent {
+			break
+		}
+		gzPath = ""
+		time.Sleep(10 * time.Millisecond)
+	}
+	if gzPath == "" {
+		t.Fatal("expected a rolled file to be compressed to .gz")
+	}
+
+	f, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gz.Close()
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello world" {
+		t.Fatalf("got decompressed content %q, want %q", content, "hello world")
+	}
+
+	if _, err := os.Stat(gzPath[:len(gzPath)-len(".gz")]); !os.IsNotExist(err) {
+		t.Fatal("expected the uncompressed rolled file to have been removed")
+	}
+}
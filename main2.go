@@ -1,281 +1,523 @@
-package main
-
-import (
-	"bufio"
-	"crypto/md5"
-	"encoding/json"
-	"flag"
-	"fmt"
-	"io"
-	"os"
-	path/filepath"
-	"regexp"
-	"sort"
-	"time"
-)
-
-type WXAnalyticsLogRecordEntry struct {
-	TimeMS             int64  `json:"timeMs"`
-	StreamID           string `json:"streamId"`
-	TotalBytesReceived int64  `json:"totalByteReceived"`
-	BytesTransferred   int64  `json:"byteTransferred"`
-	DurationMS         int64  `json:"durationMs"`
-	Width              int    `json:"width"`
-	Height             int    `json:"height"`
-           
-
-
-ype Config struct {
-	LogFilePath   string                 `json:"logFilePath"`
-	OutputFiles   map[string]string      `json:"outputFiles"`
-	EventFilters  map[string]interface{} `json:"eventFilters"`
-	BatchInterval string                 `json:"batchInterval"`
-	MonitorPeriod string                 `json:"monitorPeriod"`
-}
-
-
-const applicationLogsFile = "applicationlogs.log"
-
-var lastChecksum string
-var lastReadPosition int64 = 0
-var seenEntries = make(map[string]bool)
-
-func main() {
-
-	configFilePath := flag.String("config", "", "Path to the JSON configuration file")
-	flag.Parse()
-	if *configFilePath == "" {
-		fmt.Println("Error: Configuration file path must be specified using the -config flag.")
-		os.Exit(1)
-	}
-
-	
-	config, err := loadConfig(*configFilePath)
-	if err != nil {
-		fmt.Printf("Error loading configuration: %v\n", err)
-		return
-	}
-
-	
-	logMessage(fmt.Sprintf("Started monitoring log file: %s", config.LogFilePath))
-
-	
-	file, err := os.Open(config.LogFilePath)
-	if err != nil {
-		logMessage(fmt.Sprintf("Error opening file: %v", err))
-		return
-	}
-	defer file.Close()
-	monitorPeriod, err := time.ParseDuration(config.MonitorPeriod)
-	if err != nil {
-		logMessage(fmt.Sprintf("Invalid monitor period: %v", err))
-		return
-	}
-	ticker := time.NewTicker(monitorPeriod)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			processLogFile(file, config)
-		}
-	}
-}
-
-func loadConfig(filePath string) (*Config, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("error opening config file: %v", err)
-	}
-	defer file.Close()
-
-	var config Config
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&config)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding config file: %v", err)
-	}
-	return &config, nil
-}
-
-
-func calculateChecksum(filePath string) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("error opening log file for checksum calculation: %v", err)
-	}
-	defer file.Close()
-
-	hash := md5.New()
-	_, err = io.Copy(hash, file)
-	if err != nil {
-		return "", fmt.Errorf("error reading log file for checksum: %v", err)
-	}
-
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
-}
-
-func processLogFile(file *os.File, config *Config) {
-	checksum, err := calculateChecksum(config.LogFilePath)
-	if err != nil {
-		logMessage(fmt.Sprintf("Error calculating checksum: %v", err))
-		return
-	}
-
-	if checksum == lastChecksum {
-		logMessage("No changes detected in the log file, skipping processing.")
-		return
-	}
-	lastChecksum = checksum
-
-	_, err = file.Seek(lastReadPosition, io.SeekStart)
-	if err != nil {
-		logMessage(fmt.Sprintf("Error seeking to last read position: %v", err))
-		return
-	}
-
-	entries, err := parse(file)
-	if err != nil {
-		logMessage(fmt.Sprintf("Error parsing log file: %v", err))
-		return
-	}
-
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].TimeMS < entries[j].TimeMS
-	})
-
-	err = categorize(entries, config)
-	if err != nil {
-		logMessage(fmt.Sprintf("Error categorizing log entries: %v", err))
-	}
-
-
-	seenEntries = make(map[string]bool)
-
-	lastReadPosition, err = file.Seek(0, io.SeekCurrent)
-	if err != nil {
-		logMessage(fmt.Sprintf("Error updating last read position: %v", err))
-	}
-}
-
-
-func parse(file *os.File) ([]WXAnalyticsLogRecordEntry, error) {
-	var entries []WXAnalyticsLogRecordEntry
-	scanner := bufio.NewScanner(file)
-	jsonRegex := regexp.MustCompile(`\{.*\}`)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		matches := jsonRegex.FindString(line)
-		if matches == "" {
-			continue
-		}
-		var entry WXAnalyticsLogRecordEntry
-		err := json.Unmarshal([]byte(matches), &entry)
-		if err != nil {
-			fmt.Printf("Error parsing line: %s, error: %v\n", line, err)
-			continue
-		}
-
-		key := fmt.Sprintf("%d-%s-%s", entry.TimeMS, entry.StreamID, entry.EventType)
-		if seenEntries[key] {
-			logMessage(fmt.Sprintf("Duplicate entry detected and skipped: %v", entry))
-			continue
-		}
-		seenEntries[key] = true
-		entries = append(entries, entry)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %v", err)
-	}
-	return entries, nil
-}
-
-
-func categorize(entries []WXAnalyticsLogRecordEntry, config *Config) error {
-	for eventType, filePath := range config.OutputFiles {
-		filter, err := createFilter(eventType, config.EventFilters)
-		if err != nil {
-			logMessage(fmt.Sprintf("Error creating filter for %s: %v", eventType, err))
-			continue
-		}
-
-		var filteredEntries []WXAnalyticsLogRecordEntry
-		for _, entry := range entries {
-			if filter(entry) {
-				filteredEntries = append(filteredEntries, entry)
-			}
-		}
-
-		if len(filteredEntries) > 0 {
-			err := writeToFile(filePath, filteredEntries)
-			if err != nil {
-				logMessage(fmt.Sprintf("Error writing to file %s: %v", filePath, err))
-			}
-		}
-	}
-	return nil
-}
-
-func writeToFile(filePath string, entries []WXAnalyticsLogRecordEntry) error {
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create directories for file %s: %w", filePath, err)
-	}
-
-	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("error opening file %s: %w", filePath, err)
-	}
-	defer file.Close()
-
-	for _, entry := range entries {
-		output, err := json.Marshal(entry)
-		if err != nil {
-			return fmt.Errorf("error marshalling entry: %w", err)
-		}
-		_, err = file.WriteString(string(output) + "\n")
-		if err != nil {
-			return fmt.Errorf("error writing to file %s: %w", filePath, err)
-		}
-	}
-	return nil
-}
-
-
-func createFilter(eventType string, filters map[string]interface{}) (func(WXAnalyticsLogRecordEntry) bool, error) {
-	filter, ok := filters[eventType]
-	if !ok {
-		return nil, fmt.Errorf("no filter found for event type: %s", eventType)
-	}
-	switch v := filter.(type) {
-	case string:
-		return func(e WXAnalyticsLogRecordEntry) bool { return e.EventType == v }, nil
-	case []interface{}:
-		allowed := make(map[string]bool)
-		for _, ev := range v {
-			allowed[ev.(string)] = true
-		}
-		return func(e WXAnalyticsLogRecordEntry) bool { return allowed[e.EventType] }, nil
-	default:
-		return nil, fmt.Errorf("unsupported filter type for event type: %s", eventType)
-	}
-}
-
-
-func logMessage(message string) {
-	timestamp := time.Now().Format(time.RFC3339)
-	logEntry := fmt.Sprintf("[%s] %s\n", timestamp, message)
-
-	file, err := os.OpenFile(applicationLogsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Printf("Error writing to application logs: %v\n", err)
-		return
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(logEntry)
-	if err != nil {
-		fmt.Printf("Error writing to application logs: %v\n", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/SNEHASHISH5410/Log-analyzer/logging"
+	"github.com/SNEHASHISH5410/Log-analyzer/sink"
+	"github.com/SNEHASHISH5410/Log-analyzer/state"
+	"github.com/SNEHASHISH5410/Log-analyzer/tailer"
+)
+
+type WXAnalyticsLogRecordEntry struct {
+	TimeMS             int64  `json:"timeMs"`
+	StreamID           string `json:"streamId"`
+	EventType          string `json:"eventType"`
+	TotalBytesReceived int64  `json:"totalByteReceived"`
+	BytesTransferred   int64  `json:"byteTransferred"`
+	DurationMS         int64  `json:"durationMs"`
+	Width              int    `json:"width"`
+	Height             int    `json:"height"`
+}
+
+type Config struct {
+	LogFilePath   string                 `json:"logFilePath"`
+	Outputs       map[string]string      `json:"outputs"`
+	EventFilters  map[string]interface{} `json:"eventFilters"`
+	BatchInterval string                 `json:"batchInterval"`
+	MonitorPeriod string                 `json:"monitorPeriod"`
+	StateDir      string                 `json:"stateDir"`
+	StateBackend  string                 `json:"stateBackend"`
+	DedupTTL      string                 `json:"dedupTtl"`
+	Logging       LoggingConfig          `json:"logging"`
+}
+
+// LoggingConfig configures the application's own structured logger.
+type LoggingConfig struct {
+	Level          string `json:"level"`
+	FilePath       string `json:"filePath"`
+	MaxSizeMB      int    `json:"maxSizeMb"`
+	RotateInterval string `json:"rotateInterval"`
+	Compress       bool   `json:"compress"`
+	Stderr         bool   `json:"stderr"`
+	Syslog         string `json:"syslog"`
+}
+
+var jsonRegex = regexp.MustCompile(`\{.*\}`)
+
+// appLog is the application's own logger, initialized in main from
+// config.Logging before anything else runs.
+var appLog *logging.Logger
+
+// newLogger builds the application logger described by cfg, defaulting
+// FilePath to applicationlogs.log to match prior behavior.
+func newLogger(cfg LoggingConfig) (*logging.Logger, error) {
+	filePath := cfg.FilePath
+	if filePath == "" {
+		filePath = "applicationlogs.log"
+	}
+	rotateInterval, err := time.ParseDuration(cfg.RotateInterval)
+	if err != nil && cfg.RotateInterval != "" {
+		return nil, fmt.Errorf("invalid logging.rotateInterval: %w", err)
+	}
+	return logging.New(logging.Config{
+		Level:          cfg.Level,
+		FilePath:       filePath,
+		MaxSizeMB:      cfg.MaxSizeMB,
+		RotateInterval: rotateInterval,
+		Compress:       cfg.Compress,
+		Stderr:         cfg.Stderr,
+		Syslog:         cfg.Syslog,
+	})
+}
+
+// openStateStore builds the StateStore configured by config.StateBackend
+// ("filesystem", the default, or "bolt"), rooted at config.StateDir.
+func openStateStore(config *Config) (state.StateStore, error) {
+	stateDir := config.StateDir
+	if stateDir == "" {
+		stateDir = "."
+	}
+	switch config.StateBackend {
+	case "", "filesystem":
+		return state.NewFilesystemState(stateDir)
+	case "bolt":
+		return state.NewBoltState(filepath.Join(stateDir, "state.bolt"))
+	default:
+		return nil, fmt.Errorf("unsupported stateBackend %q", config.StateBackend)
+	}
+}
+
+// configState guards the subset of Config that SIGHUP can swap in at
+// runtime (Outputs, EventFilters, BatchInterval) without restarting the
+// tailer or its open file handles.
+type configState struct {
+	mu  sync.RWMutex
+	cfg *Config
+}
+
+func (c *configState) get() *Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cfg
+}
+
+func (c *configState) set(cfg *Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+}
+
+// runtimeStats accumulates the counters SIGUSR1 dumps to the application
+// log: entries parsed, duplicates dropped, and writes per output category.
+type runtimeStats struct {
+	parsed     int64
+	duplicates int64
+
+	mu     sync.Mutex
+	writes map[string]int64
+}
+
+func newRuntimeStats() *runtimeStats {
+	return &runtimeStats{writes: make(map[string]int64)}
+}
+
+func (s *runtimeStats) recordParsed()    { atomic.AddInt64(&s.parsed, 1) }
+func (s *runtimeStats) recordDuplicate() { atomic.AddInt64(&s.duplicates, 1) }
+
+func (s *runtimeStats) recordWrites(eventType string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes[eventType] += int64(n)
+}
+
+func (s *runtimeStats) writesSnapshot() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]int64, len(s.writes))
+	for k, v := range s.writes {
+		out[k] = v
+	}
+	return out
+}
+
+// dump logs a snapshot of runtime counters plus the store's current
+// per-source offsets and seen-entry set size, in response to SIGUSR1.
+func (s *runtimeStats) dump(store state.StateStore, sources []string) {
+	offsets := make(map[string]int64, len(sources))
+	for _, src := range sources {
+		if off, err := store.GetOffset(src); err == nil {
+			offsets[src] = off
+		}
+	}
+
+	fields := []interface{}{
+		"parsed", atomic.LoadInt64(&s.parsed),
+		"duplicates_dropped", atomic.LoadInt64(&s.duplicates),
+		"writes_by_category", s.writesSnapshot(),
+		"offsets_by_source", offsets,
+	}
+	if counter, ok := store.(interface{ SeenCount() int }); ok {
+		fields = append(fields, "seen_entries_size", counter.SeenCount())
+	}
+	appLog.Info("Runtime stats", fields...)
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "replay" {
+		runReplay(os.Args[2:])
+		return
+	}
+	runLive()
+}
+
+// runLive is the default mode: tail config.LogFilePath live and dispatch
+// parsed entries to the configured sinks until signaled to stop.
+func runLive() {
+
+	configFilePath := flag.String("config", "", "Path to the JSON configuration file")
+	flag.Parse()
+	if *configFilePath == "" {
+		fmt.Println("Error: Configuration file path must be specified using the -config flag.")
+		os.Exit(1)
+	}
+
+	config, err := loadConfig(*configFilePath)
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		return
+	}
+
+	appLog, err = newLogger(config.Logging)
+	if err != nil {
+		fmt.Printf("Error initializing logger: %v\n", err)
+		return
+	}
+	defer appLog.Close()
+
+	appLog.Info("Started monitoring log file(s)", "source", config.LogFilePath)
+
+	batchInterval, err := time.ParseDuration(config.BatchInterval)
+	if err != nil {
+		appLog.Error("Invalid batch interval", "error", err)
+		return
+	}
+
+	dedupTTL, err := parseDedupTTL(config.DedupTTL)
+	if err != nil {
+		appLog.Error("Invalid dedup TTL", "error", err)
+		return
+	}
+
+	store, err := openStateStore(config)
+	if err != nil {
+		appLog.Error("Error opening state store", "error", err)
+		return
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t, err := tailer.New(config.LogFilePath, store)
+	if err != nil {
+		appLog.Error("Error creating tailer", "error", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	if err := t.Start(ctx, &wg); err != nil {
+		appLog.Error("Error starting tailer", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	sinks := make(map[string]*cachedSink)
+	defer closeSinks(sinks)
+
+	cs := &configState{cfg: config}
+	stats := newRuntimeStats()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
+	defer signal.Stop(sigCh)
+
+	var pending []WXAnalyticsLogRecordEntry
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		cfg := cs.get()
+		sort.Slice(pending, func(i, j int) bool {
+			return pending[i].TimeMS < pending[j].TimeMS
+		})
+		if err := categorize(ctx, pending, cfg, sinks, stats); err != nil {
+			appLog.Error("Error categorizing log entries", "error", err)
+		}
+		pending = nil
+		if err := t.Checkpoint(); err != nil {
+			appLog.Error("Error writing tailer checkpoint", "error", err)
+		}
+	}
+
+	// shutdown drains any in-flight batch, stops and joins the tailer
+	// goroutines, and flushes state before runLive returns (whose deferred
+	// Close calls then release the store, sinks and logger).
+	shutdown := func() {
+		flush()
+		cancel()
+		wg.Wait()
+		if err := t.Checkpoint(); err != nil {
+			appLog.Error("Error writing final checkpoint", "error", err)
+		}
+	}
+
+	// reload re-reads the config file and swaps in the new Outputs,
+	// EventFilters and BatchInterval without restarting the tailer or its
+	// open file handles.
+	reload := func() {
+		reloaded, err := loadConfig(*configFilePath)
+		if err != nil {
+			appLog.Error("Error reloading configuration", "error", err)
+			return
+		}
+		newInterval, err := time.ParseDuration(reloaded.BatchInterval)
+		if err != nil {
+			appLog.Error("Invalid batch interval in reloaded configuration", "error", err)
+			return
+		}
+		cs.set(reloaded)
+		if newInterval != batchInterval {
+			batchInterval = newInterval
+			ticker.Reset(batchInterval)
+		}
+		appLog.Info("Reloaded configuration")
+	}
+
+	for {
+		select {
+		case sig := <-sigCh:
+			switch sig {
+			case syscall.SIGHUP:
+				reload()
+			case syscall.SIGUSR1:
+				stats.dump(store, globSources(config.LogFilePath))
+			default:
+				appLog.Info("Received shutdown signal", "signal", sig.String())
+				shutdown()
+				return
+			}
+		case line, ok := <-t.Lines():
+			if !ok {
+				shutdown()
+				return
+			}
+			entry, ok := parseLine(store, dedupTTL, line.Text, stats)
+			if ok {
+				pending = append(pending, entry)
+			}
+			// Advance only now that the line has actually been taken off
+			// Lines() and handed to parseLine, not when the tailer merely
+			// read it off disk — otherwise a line still sitting in the
+			// channel's buffer at shutdown would already be checkpointed
+			// past, and a restart would silently skip it.
+			if err := t.Advance(line); err != nil {
+				appLog.Error("Error recording tailer offset", "error", err)
+			}
+		case err := <-t.Errs():
+			appLog.Error("Tailer error", "error", err)
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func loadConfig(filePath string) (*Config, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening config file: %v", err)
+	}
+	defer file.Close()
+
+	var config Config
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&config)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding config file: %v", err)
+	}
+	return &config, nil
+}
+
+// parseDedupTTL parses config.DedupTTL, defaulting to 24h so the seen-entry
+// set in store doesn't grow without bound.
+func parseDedupTTL(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// globSources expands a tailer glob pattern to the file paths it currently
+// matches, for reporting per-source offsets in a stats dump.
+func globSources(pattern string) []string {
+	matches, err := filepath.Glob(pattern)
+	if err != nil || len(matches) == 0 {
+		return []string{pattern}
+	}
+	return matches
+}
+
+// parseLine extracts a WXAnalyticsLogRecordEntry from a single tailed line,
+// deduplicating against entries already recorded in store so duplicates
+// don't reappear across restarts.
+func parseLine(store state.StateStore, dedupTTL time.Duration, line string, stats *runtimeStats) (WXAnalyticsLogRecordEntry, bool) {
+	matches := jsonRegex.FindString(line)
+	if matches == "" {
+		return WXAnalyticsLogRecordEntry{}, false
+	}
+	var entry WXAnalyticsLogRecordEntry
+	if err := json.Unmarshal([]byte(matches), &entry); err != nil {
+		appLog.Debug("Error parsing line", "line", line, "error", err)
+		return WXAnalyticsLogRecordEntry{}, false
+	}
+
+	key := fmt.Sprintf("%d-%s-%s", entry.TimeMS, entry.StreamID, entry.EventType)
+	seen, err := store.SeenEntry(key)
+	if err != nil {
+		appLog.Error("Error checking seen entry", "key", key, "error", err)
+	}
+	if seen {
+		stats.recordDuplicate()
+		appLog.Debug("Duplicate entry detected and skipped", "stream_id", entry.StreamID, "key", key)
+		return WXAnalyticsLogRecordEntry{}, false
+	}
+	if err := store.MarkSeen(key, dedupTTL); err != nil {
+		appLog.Error("Error marking entry seen", "key", key, "error", err)
+	}
+	stats.recordParsed()
+	return entry, true
+}
+
+// categorize filters entries per configured event category and dispatches
+// each filtered batch to that category's resolved Sink, reusing already-open
+// sinks in sinks across calls.
+func categorize(ctx context.Context, entries []WXAnalyticsLogRecordEntry, config *Config, sinks map[string]*cachedSink, stats *runtimeStats) error {
+	for eventType, sinkURI := range config.Outputs {
+		filter, err := createFilter(eventType, config.EventFilters)
+		if err != nil {
+			appLog.Error("Error creating filter", "event_type", eventType, "error", err)
+			continue
+		}
+
+		var filteredEntries []WXAnalyticsLogRecordEntry
+		for _, entry := range entries {
+			if filter(entry) {
+				filteredEntries = append(filteredEntries, entry)
+			}
+		}
+		if len(filteredEntries) == 0 {
+			continue
+		}
+
+		s, err := resolveSink(sinks, eventType, sinkURI)
+		if err != nil {
+			appLog.Error("Error resolving sink", "event_type", eventType, "error", err)
+			continue
+		}
+
+		sinkEntries := make([]sink.Entry, len(filteredEntries))
+		for i, entry := range filteredEntries {
+			sinkEntries[i] = entryToSinkEntry(entry)
+		}
+		if err := s.Write(ctx, sinkEntries); err != nil {
+			appLog.Error("Error writing to sink", "sink", sinkURI, "error", err)
+			continue
+		}
+		stats.recordWrites(eventType, len(sinkEntries))
+	}
+	return nil
+}
+
+// cachedSink pairs an open Sink with the URI it was resolved from, so a
+// SIGHUP reload that changes an eventType's output URI can detect the
+// mismatch instead of reusing a sink bound to the old destination.
+type cachedSink struct {
+	uri string
+	s   sink.Sink
+}
+
+// resolveSink returns the already-open Sink for eventType, creating and
+// caching it from sinkURI on first use. If eventType was previously resolved
+// against a different URI - e.g. after a config reload - the stale sink is
+// closed and replaced.
+func resolveSink(sinks map[string]*cachedSink, eventType, sinkURI string) (sink.Sink, error) {
+	if c, ok := sinks[eventType]; ok {
+		if c.uri == sinkURI {
+			return c.s, nil
+		}
+		if err := c.s.Close(); err != nil {
+			appLog.Error("Error closing stale sink", "event_type", eventType, "uri", c.uri, "error", err)
+		}
+		delete(sinks, eventType)
+	}
+
+	s, err := sink.Resolve(sinkURI)
+	if err != nil {
+		return nil, err
+	}
+	sinks[eventType] = &cachedSink{uri: sinkURI, s: s}
+	return s, nil
+}
+
+func entryToSinkEntry(entry WXAnalyticsLogRecordEntry) sink.Entry {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return sink.Entry{}
+	}
+	var out sink.Entry
+	if err := json.Unmarshal(data, &out); err != nil {
+		return sink.Entry{}
+	}
+	return out
+}
+
+func closeSinks(sinks map[string]*cachedSink) {
+	for name, c := range sinks {
+		if err := c.s.Close(); err != nil {
+			appLog.Error("Error closing sink", "event_type", name, "error", err)
+		}
+	}
+}
+
+func createFilter(eventType string, filters map[string]interface{}) (func(WXAnalyticsLogRecordEntry) bool, error) {
+	filter, ok := filters[eventType]
+	if !ok {
+		return nil, fmt.Errorf("no filter found for event type: %s", eventType)
+	}
+	switch v := filter.(type) {
+	case string:
+		return func(e WXAnalyticsLogRecordEntry) bool { return e.EventType == v }, nil
+	case []interface{}:
+		allowed := make(map[string]bool)
+		for _, ev := range v {
+			allowed[ev.(string)] = true
+		}
+		return func(e WXAnalyticsLogRecordEntry) bool { return allowed[e.EventType] }, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter type for event type: %s", eventType)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SNEHASHISH5410/Log-analyzer/logging"
+	"github.com/SNEHASHISH5410/Log-analyzer/state"
+)
+
+func TestMain(m *testing.M) {
+	l, err := logging.New(logging.Config{Level: "error"})
+	if err != nil {
+		panic(err)
+	}
+	appLog = l
+	os.Exit(m.Run())
+}
+
+// TestParseLineDedupsAcrossCalls exercises the path runReplay now shares with
+// live tailing: the same record appearing in two different (overlapping)
+// historical files must only be counted once.
+func TestParseLineDedupsAcrossCalls(t *testing.T) {
+	store, err := state.NewFilesystemState(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	stats := newRuntimeStats()
+	line := `{"timeMs":1000,"streamId":"s1","eventType":"play"}`
+
+	_, ok := parseLine(store, time.Hour, line, stats)
+	if !ok {
+		t.Fatal("expected the first occurrence to parse")
+	}
+	_, ok = parseLine(store, time.Hour, line, stats)
+	if ok {
+		t.Fatal("expected the duplicate occurrence (as from an overlapping replay file) to be dropped")
+	}
+
+	if stats.parsed != 1 {
+		t.Fatalf("expected 1 parsed entry, got %d", stats.parsed)
+	}
+	if stats.duplicates != 1 {
+		t.Fatalf("expected 1 duplicate recorded, got %d", stats.duplicates)
+	}
+}
+
+// TestParseLineSkipsNonJSONQuietly ensures a line with no embedded JSON
+// (e.g. a log banner line in a replayed file) is dropped without being
+// reported as a parse error.
+func TestParseLineSkipsNonJSONQuietly(t *testing.T) {
+	store, err := state.NewFilesystemState(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	stats := newRuntimeStats()
+	_, ok := parseLine(store, time.Hour, "-- log rotated at startup --", stats)
+	if ok {
+		t.Fatal("expected a non-JSON line to be skipped")
+	}
+	if stats.parsed != 0 || stats.duplicates != 0 {
+		t.Fatalf("expected no counters touched for a skipped line, got parsed=%d duplicates=%d", stats.parsed, stats.duplicates)
+	}
+}
+
+func TestOpenStateStoreDefaultsToFilesystem(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	store, err := openStateStore(&Config{StateDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if _, ok := store.(*state.FilesystemState); !ok {
+		t.Fatalf("expected a FilesystemState by default, got %T", store)
+	}
+}
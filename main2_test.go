@@ -0,0 +1,51 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSinkCachesPerURI(t *testing.T) {
+	dir := t.TempDir()
+	sinks := make(map[string]*cachedSink)
+
+	uriA := "file://" + filepath.Join(dir, "a.log")
+	s1, err := resolveSink(sinks, "metrics", uriA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := resolveSink(sinks, "metrics", uriA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s1 != s2 {
+		t.Fatal("expected the same sink instance to be reused for an unchanged URI")
+	}
+}
+
+func TestResolveSinkReplacesStaleURI(t *testing.T) {
+	dir := t.TempDir()
+	sinks := make(map[string]*cachedSink)
+
+	uriA := "file://" + filepath.Join(dir, "a.log")
+	uriB := "file://" + filepath.Join(dir, "b.log")
+
+	s1, err := resolveSink(sinks, "metrics", uriA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, err := resolveSink(sinks, "metrics", uriB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s1 == s2 {
+		t.Fatal("expected a new sink after the eventType's URI changed")
+	}
+	if got := sinks["metrics"].uri; got != uriB {
+		t.Fatalf("expected cache to track the new URI, got %q", got)
+	}
+	if len(sinks) != 1 {
+		t.Fatalf("expected exactly one cache entry for metrics, got %d", len(sinks))
+	}
+}
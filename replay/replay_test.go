@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeGzFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRunMergesInTimestampOrder checks the k-way merge interleaves lines
+// from multiple source files strictly by ascending TimeMS, regardless of
+// which file they came from or its read order.
+func TestRunMergesInTimestampOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.log"), `{"timeMs":100}
+{"timeMs":300}
+`)
+	writeFile(t, filepath.Join(dir, "b.log"), `{"timeMs":200}
+{"timeMs":400}
+`)
+
+	var got []int64
+	err := Run(context.Background(), filepath.Join(dir, "*.log"), func(l Line) error {
+		got = append(got, l.TimeMS)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int64{100, 200, 300, 400}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRunDecompressesGzip(t *testing.T) {
+	dir := t.TempDir()
+	writeGzFile(t, filepath.Join(dir, "a.log.gz"), `{"timeMs":1}
+{"timeMs":2}
+`)
+
+	var got []int64
+	err := Run(context.Background(), filepath.Join(dir, "*.gz"), func(l Line) error {
+		got = append(got, l.TimeMS)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("got %v, want [1 2]", got)
+	}
+}
+
+func TestRunSkipsLinesWithoutTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.log"), "-- banner --\n{\"timeMs\":5}\nnot json either\n")
+
+	var got []int64
+	err := Run(context.Background(), filepath.Join(dir, "*.log"), func(l Line) error {
+		got = append(got, l.TimeMS)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != 5 {
+		t.Fatalf("got %v, want [5]", got)
+	}
+}
+
+func TestRunNoMatchesIsNotAnError(t *testing.T) {
+	dir := t.TempDir()
+	if err := Run(context.Background(), filepath.Join(dir, "*.log"), func(Line) error {
+		t.Fatal("handler should not be called when nothing matches")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,58 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileSink appends newline-delimited JSON to a local file, preserving the
+// original writeToFile behavior.
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newFileSink(u *url.URL) (Sink, error) {
+	path := u.Path
+	if path == "" {
+		path = u.Opaque
+	}
+	if path == "" {
+		return nil, fmt.Errorf("sink: file URI missing path")
+	}
+	return &fileSink{path: path}, nil
+}
+
+func (s *fileSink) Write(_ context.Context, entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directories for file %s: %w", s.path, err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening file %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	for _, entry := range entries {
+		output, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error marshalling entry: %w", err)
+		}
+		if _, err := file.Write(append(output, '\n')); err != nil {
+			return fmt.Errorf("error writing to file %s: %w", s.path, err)
+		}
+	}
+	return nil
+}
+
+func (s *fileSink) Close() error { return nil }
@@ -0,0 +1,53 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// kafkaSink publishes each entry as a JSON-encoded Kafka message.
+type kafkaSink struct {
+	writer *kafka.Writer
+}
+
+func newKafkaSink(u *url.URL) (Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("sink: kafka URI missing broker host")
+	}
+	topic := strings.TrimPrefix(u.Path, "/")
+	if topic == "" {
+		return nil, fmt.Errorf("sink: kafka URI missing topic")
+	}
+
+	return &kafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(u.Host),
+			Topic:    topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}, nil
+}
+
+func (s *kafkaSink) Write(ctx context.Context, entries []Entry) error {
+	messages := make([]kafka.Message, 0, len(entries))
+	for _, entry := range entries {
+		value, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("kafka sink: marshalling entry: %w", err)
+		}
+		messages = append(messages, kafka.Message{Value: value})
+	}
+	if err := s.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("kafka sink: writing messages: %w", err)
+	}
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,34 @@
+package sink
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNewKafkaSinkMissingHost(t *testing.T) {
+	u, _ := url.Parse("kafka:///my-topic")
+	if _, err := newKafkaSink(u); err == nil {
+		t.Fatal("expected an error for a kafka URI with no broker host")
+	}
+}
+
+func TestNewKafkaSinkMissingTopic(t *testing.T) {
+	u, _ := url.Parse("kafka://localhost:9092")
+	if _, err := newKafkaSink(u); err == nil {
+		t.Fatal("expected an error for a kafka URI with no topic")
+	}
+}
+
+func TestNewKafkaSinkTopicLeadingSlash(t *testing.T) {
+	u, _ := url.Parse("kafka://localhost:9092/my-topic")
+	s, err := newKafkaSink(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	got := s.(*kafkaSink).writer.Topic
+	if want := "my-topic"; got != want {
+		t.Fatalf("topic = %q, want %q (leading slash should be stripped)", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+// Package sink resolves output URIs (file://, kafka://, http+webhook://,
+// https+webhook://, syslog://) into Sink implementations that categorized
+// log entries are dispatched to.
+package sink
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// Entry is the subset of a log record a Sink needs to serialize; callers
+// convert their own record type into this generic JSON object form before
+// passing it to Write.
+type Entry = map[string]interface{}
+
+// Sink delivers a batch of entries to a downstream system.
+type Sink interface {
+	Write(ctx context.Context, entries []Entry) error
+	Close() error
+}
+
+// Resolve parses a sink URI and constructs the matching Sink implementation.
+// Supported schemes: file, kafka, http+webhook, https+webhook, syslog.
+func Resolve(rawURI string) (Sink, error) {
+	u, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, fmt.Errorf("sink: invalid URI %q: %w", rawURI, err)
+	}
+
+	switch u.Scheme {
+	case "file", "":
+		return newFileSink(u)
+	case "kafka":
+		return newKafkaSink(u)
+	case "http+webhook":
+		return newWebhookSink(u, "http")
+	case "https+webhook":
+		return newWebhookSink(u, "https")
+	case "syslog":
+		return newSyslogSink(u)
+	default:
+		return nil, fmt.Errorf("sink: unsupported scheme %q in %q", u.Scheme, rawURI)
+	}
+}
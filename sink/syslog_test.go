@@ -0,0 +1,55 @@
+package sink
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// frameRE matches an RFC 5424 header: <PRI>VERSION TIMESTAMP HOSTNAME
+// APP-NAME PROCID MSGID SD MSG.
+var frameRE = regexp.MustCompile(`^<\d+>1 \S+ \S+ log-analyzer \d+ - - (.+)$`)
+
+func TestSyslogSinkFramesRFC5424(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	u, _ := url.Parse("syslog://" + conn.LocalAddr().String() + "?facility=local3")
+	s, err := newSyslogSink(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Write(context.Background(), []Entry{{"msg": "hello"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(buf[:n])
+	m := frameRE.FindStringSubmatch(got)
+	if m == nil {
+		t.Fatalf("frame %q does not match RFC 5424 header format", got)
+	}
+	if want := `{"msg":"hello"}`; m[1] != want {
+		t.Fatalf("got message %q, want %q", m[1], want)
+	}
+
+	// local3 (19) shifted for the facility field, ORed with informational (6).
+	wantPri := "<" + "158" + ">1"
+	if got[:len(wantPri)] != wantPri {
+		t.Fatalf("got PRI prefix %q, want %q (facility=local3, severity=info)", got[:len(wantPri)], wantPri)
+	}
+}
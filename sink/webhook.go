@@ -0,0 +1,104 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// webhookSink batches entries and POSTs them as a JSON array, retrying with
+// exponential backoff on transport errors or 5xx responses.
+type webhookSink struct {
+	endpoint   string
+	batchSize  int
+	maxRetries int
+	client     *http.Client
+}
+
+func newWebhookSink(u *url.URL, scheme string) (Sink, error) {
+	endpoint := (&url.URL{Scheme: scheme, Host: u.Host, Path: u.Path, RawQuery: ""}).String()
+
+	batchSize := 50
+	maxRetries := 3
+	q := u.Query()
+	if v := q.Get("batch"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			batchSize = n
+		}
+	}
+	if v := q.Get("retries"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxRetries = n
+		}
+	}
+
+	return &webhookSink{
+		endpoint:   endpoint,
+		batchSize:  batchSize,
+		maxRetries: maxRetries,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *webhookSink) Write(ctx context.Context, entries []Entry) error {
+	for start := 0; start < len(entries); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		if err := s.postBatch(ctx, entries[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *webhookSink) postBatch(ctx context.Context, batch []Entry) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("webhook sink: marshalling batch: %w", err)
+	}
+
+	var lastErr error
+	backoff := 200 * time.Millisecond
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("webhook sink: building request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return nil
+			}
+			lastErr = fmt.Errorf("webhook sink: server returned %d", resp.StatusCode)
+			if resp.StatusCode < 500 {
+				// 4xx won't be fixed by retrying the same payload.
+				return lastErr
+			}
+		} else {
+			lastErr = fmt.Errorf("webhook sink: request failed: %w", err)
+		}
+
+		if attempt == s.maxRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return lastErr
+}
+
+func (s *webhookSink) Close() error { return nil }
@@ -0,0 +1,73 @@
+package sink
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWebhookSinkRejectsClientError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse("http+webhook://" + srv.Listener.Addr().String() + "?retries=2")
+	s, err := newWebhookSink(u, "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Write(context.Background(), []Entry{{"a": 1}})
+	if err == nil {
+		t.Fatal("expected a 422 response to surface as an error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected a 4xx response to not be retried, got %d calls", got)
+	}
+}
+
+func TestWebhookSinkRetriesServerError(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse("http+webhook://" + srv.Listener.Addr().String() + "?retries=5")
+	s, err := newWebhookSink(u, "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Write(context.Background(), []Entry{{"a": 1}}); err != nil {
+		t.Fatalf("expected eventual success after retries, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWebhookSinkHTTPSScheme(t *testing.T) {
+	u, _ := url.Parse("https+webhook://example.com/hook?batch=10")
+	s, err := newWebhookSink(u, "https")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.(*webhookSink).endpoint
+	want := "https://example.com/hook"
+	if got != want {
+		t.Fatalf("endpoint = %q, want %q", got, want)
+	}
+}
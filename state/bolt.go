@@ -0,0 +1,150 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	offsetsBucket = []byte("offsets")
+	seenBucket    = []byte("seen")
+)
+
+// BoltState is a StateStore backed by a bbolt database, with TTL-based
+// expiry on the seen-entry bucket so long-running processes don't grow the
+// dedup set without bound.
+type BoltState struct {
+	db *bolt.DB
+}
+
+// NewBoltState opens (creating if necessary) a bbolt database at path.
+func NewBoltState(path string) (*BoltState, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("state: opening bolt db %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(offsetsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(seenBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("state: initializing buckets: %w", err)
+	}
+	return &BoltState{db: db}, nil
+}
+
+// SeenCount reports how many entries (expired or not) are currently stored
+// in the seen bucket. It is not part of the StateStore interface; callers
+// that want it (e.g. a stats dump) type-assert for it.
+func (b *BoltState) SeenCount() int {
+	count := 0
+	b.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(seenBucket).Stats().KeyN
+		return nil
+	})
+	return count
+}
+
+func (b *BoltState) GetOffset(source string) (int64, error) {
+	var offset int64
+	err := b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(offsetsBucket).Get([]byte(source))
+		if raw == nil {
+			return nil
+		}
+		offset = int64(binary.BigEndian.Uint64(raw))
+		return nil
+	})
+	return offset, err
+}
+
+func (b *BoltState) SetOffset(source string, offset int64) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		raw := make([]byte, 8)
+		binary.BigEndian.PutUint64(raw, uint64(offset))
+		return tx.Bucket(offsetsBucket).Put([]byte(source), raw)
+	})
+}
+
+func (b *BoltState) SeenEntry(key string) (bool, error) {
+	seen := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(seenBucket)
+		raw := bucket.Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		expiresAt := decodeExpiry(raw)
+		if !expiresAt.IsZero() && time.Now().After(expiresAt) {
+			return bucket.Delete([]byte(key))
+		}
+		seen = true
+		return nil
+	})
+	return seen, err
+}
+
+func (b *BoltState) MarkSeen(key string, ttl time.Duration) error {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(seenBucket).Put([]byte(key), encodeExpiry(expiresAt))
+	})
+}
+
+// Checkpoint flushes any pending expired seen-entries and syncs the
+// underlying file; bbolt commits each Update transaction durably already,
+// so this mainly exists to satisfy the StateStore contract.
+func (b *BoltState) Checkpoint() error {
+	now := time.Now()
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(seenBucket)
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			expiresAt := decodeExpiry(v)
+			if !expiresAt.IsZero() && now.After(expiresAt) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltState) Close() error {
+	return b.db.Close()
+}
+
+func encodeExpiry(t time.Time) []byte {
+	raw := make([]byte, 8)
+	if t.IsZero() {
+		return raw
+	}
+	binary.BigEndian.PutUint64(raw, uint64(t.UnixNano()))
+	return raw
+}
+
+func decodeExpiry(raw []byte) time.Time {
+	nanos := binary.BigEndian.Uint64(raw)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(nanos))
+}
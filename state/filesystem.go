@@ -0,0 +1,155 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type seenRecord struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// FilesystemState persists each source's read offset in its own file under
+// stateDir/offsets, written via a temp file + atomic rename on every
+// SetOffset so a crash mid-write can never corrupt another source's
+// checkpoint or force a re-marshal of unrelated state. The seen-entry dedup
+// set isn't scoped to any one source, so it's kept in a single
+// temp-file-plus-rename checkpoint instead.
+type FilesystemState struct {
+	stateDir string
+	seenPath string
+
+	mu   sync.Mutex
+	seen map[string]seenRecord
+}
+
+// NewFilesystemState opens (or initializes) a FilesystemState rooted at
+// stateDir.
+func NewFilesystemState(stateDir string) (*FilesystemState, error) {
+	offsetsDir := filepath.Join(stateDir, "offsets")
+	if err := os.MkdirAll(offsetsDir, 0755); err != nil {
+		return nil, fmt.Errorf("state: creating offsets dir %s: %w", offsetsDir, err)
+	}
+
+	fs := &FilesystemState{
+		stateDir: stateDir,
+		seenPath: filepath.Join(stateDir, "seen.json"),
+		seen:     make(map[string]seenRecord),
+	}
+
+	raw, err := os.ReadFile(fs.seenPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fs, nil
+		}
+		return nil, fmt.Errorf("state: reading %s: %w", fs.seenPath, err)
+	}
+	if err := json.Unmarshal(raw, &fs.seen); err != nil {
+		return nil, fmt.Errorf("state: decoding %s: %w", fs.seenPath, err)
+	}
+	return fs, nil
+}
+
+// SeenCount reports how many entries are currently tracked for
+// deduplication, including expired ones that neither SeenEntry nor
+// Checkpoint has pruned yet. It is not part of the StateStore interface; callers
+// that want it (e.g. a stats dump) type-assert for it.
+func (fs *FilesystemState) SeenCount() int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return len(fs.seen)
+}
+
+// offsetPath maps source to its own checkpoint file, named by hash since
+// source is typically an absolute path and may contain separators.
+func (fs *FilesystemState) offsetPath(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(fs.stateDir, "offsets", hex.EncodeToString(sum[:])+".offset")
+}
+
+func (fs *FilesystemState) GetOffset(source string) (int64, error) {
+	raw, err := os.ReadFile(fs.offsetPath(source))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("state: reading offset for %s: %w", source, err)
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(raw)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("state: parsing offset for %s: %w", source, err)
+	}
+	return offset, nil
+}
+
+func (fs *FilesystemState) SetOffset(source string, offset int64) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	path := fs.offsetPath(source)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(offset, 10)), 0644); err != nil {
+		return fmt.Errorf("state: writing offset for %s: %w", source, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (fs *FilesystemState) SeenEntry(key string) (bool, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	rec, ok := fs.seen[key]
+	if !ok {
+		return false, nil
+	}
+	if !rec.ExpiresAt.IsZero() && time.Now().After(rec.ExpiresAt) {
+		delete(fs.seen, key)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (fs *FilesystemState) MarkSeen(key string, ttl time.Duration) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	fs.seen[key] = seenRecord{ExpiresAt: expiresAt}
+	return nil
+}
+
+// Checkpoint flushes the seen set to disk, pruning expired entries so the
+// file doesn't grow unbounded. Offsets need no separate flush here: each
+// SetOffset already wrote its own source's file atomically.
+func (fs *FilesystemState) Checkpoint() error {
+	fs.mu.Lock()
+	now := time.Now()
+	for key, rec := range fs.seen {
+		if !rec.ExpiresAt.IsZero() && now.After(rec.ExpiresAt) {
+			delete(fs.seen, key)
+		}
+	}
+	raw, err := json.Marshal(fs.seen)
+	fs.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("state: marshalling checkpoint: %w", err)
+	}
+
+	tmp := fs.seenPath + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf("state: writing checkpoint: %w", err)
+	}
+	return os.Rename(tmp, fs.seenPath)
+}
+
+func (fs *FilesystemState) Close() error {
+	return fs.Checkpoint()
+}
@@ -0,0 +1,31 @@
+// Package state abstracts the checkpoint/dedup bookkeeping the analyzer
+// needs to be restart-safe: per-source read offsets and a seen-entry set
+// used to drop duplicates across runs.
+package state
+
+import "time"
+
+// StateStore persists tailing offsets and a deduplication set across
+// restarts. Implementations must be safe for concurrent use.
+type StateStore interface {
+	// GetOffset returns the last persisted read offset for source, or 0 if
+	// none has been recorded yet.
+	GetOffset(source string) (int64, error)
+
+	// SetOffset records the read offset for source.
+	SetOffset(source string, offset int64) error
+
+	// SeenEntry reports whether key has already been recorded via MarkSeen
+	// and has not yet expired.
+	SeenEntry(key string) (bool, error)
+
+	// MarkSeen records key as seen, expiring it after ttl. A ttl of 0 means
+	// the entry never expires.
+	MarkSeen(key string, ttl time.Duration) error
+
+	// Checkpoint durably flushes any buffered state to storage.
+	Checkpoint() error
+
+	// Close releases any resources held by the store.
+	Close() error
+}
@@ -0,0 +1,169 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newStores(t *testing.T) map[string]StateStore {
+	t.Helper()
+	dir := t.TempDir()
+
+	fsState, err := NewFilesystemState(filepath.Join(dir, "fs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	boltState, err := NewBoltState(filepath.Join(dir, "bolt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		fsState.Close()
+		boltState.Close()
+	})
+
+	return map[string]StateStore{
+		"filesystem": fsState,
+		"bolt":       boltState,
+	}
+}
+
+func TestStateStoreOffsets(t *testing.T) {
+	for name, store := range newStores(t) {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			if off, err := store.GetOffset("a.log"); err != nil || off != 0 {
+				t.Fatalf("expected 0 offset for unseen source, got %d, %v", off, err)
+			}
+			if err := store.SetOffset("a.log", 42); err != nil {
+				t.Fatal(err)
+			}
+			if off, err := store.GetOffset("a.log"); err != nil || off != 42 {
+				t.Fatalf("got offset %d, %v, want 42", off, err)
+			}
+		})
+	}
+}
+
+func TestStateStoreDedupTTL(t *testing.T) {
+	for name, store := range newStores(t) {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			seen, err := store.SeenEntry("k1")
+			if err != nil || seen {
+				t.Fatalf("expected k1 unseen initially, got %v, %v", seen, err)
+			}
+
+			if err := store.MarkSeen("k1", 30*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			seen, err = store.SeenEntry("k1")
+			if err != nil || !seen {
+				t.Fatalf("expected k1 seen immediately after MarkSeen, got %v, %v", seen, err)
+			}
+
+			time.Sleep(60 * time.Millisecond)
+			seen, err = store.SeenEntry("k1")
+			if err != nil || seen {
+				t.Fatalf("expected k1 to have expired, got %v, %v", seen, err)
+			}
+		})
+	}
+}
+
+func TestStateStoreDedupNeverExpires(t *testing.T) {
+	for name, store := range newStores(t) {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			if err := store.MarkSeen("k2", 0); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(20 * time.Millisecond)
+			seen, err := store.SeenEntry("k2")
+			if err != nil || !seen {
+				t.Fatalf("expected a zero TTL entry to never expire, got %v, %v", seen, err)
+			}
+		})
+	}
+}
+
+func TestStateStoreCheckpointPrunesExpired(t *testing.T) {
+	for name, store := range newStores(t) {
+		store := store
+		t.Run(name, func(t *testing.T) {
+			type seenCounter interface{ SeenCount() int }
+			counter, ok := store.(seenCounter)
+			if !ok {
+				t.Fatalf("%T does not implement SeenCount", store)
+			}
+
+			if err := store.MarkSeen("expiring", 20*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.MarkSeen("persistent", 0); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(40 * time.Millisecond)
+
+			if err := store.Checkpoint(); err != nil {
+				t.Fatal(err)
+			}
+			if got := counter.SeenCount(); got != 1 {
+				t.Fatalf("expected 1 entry left after pruning, got %d", got)
+			}
+		})
+	}
+}
+
+// TestFilesystemStateOneFilePerSource pins FilesystemState's on-disk layout:
+// each source gets its own offset checkpoint file, written immediately
+// (rather than batched into one combined blob), so a glob of many source
+// files doesn't pay to re-marshal every other source's offset - or the
+// whole dedup set - each time just one of them advances.
+func TestFilesystemStateOneFilePerSource(t *testing.T) {
+	dir := t.TempDir()
+	fs, err := NewFilesystemState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	sources := []string{"/var/log/wx/a.log", "/var/log/wx/b.log", "/var/log/wx/c.log"}
+	for i, src := range sources {
+		if err := fs.SetOffset(src, int64(i+1)*100); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "offsets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(sources) {
+		t.Fatalf("expected one offset file per source, got %d entries for %d sources", len(entries), len(sources))
+	}
+
+	// Advancing one source's offset must not touch the others' files nor
+	// require the seen-entry set to be present on disk at all yet.
+	if err := fs.SetOffset(sources[0], 150); err != nil {
+		t.Fatal(err)
+	}
+	for i, src := range sources {
+		off, err := fs.GetOffset(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := int64(i+1) * 100
+		if i == 0 {
+			want = 150
+		}
+		if off != want {
+			t.Fatalf("source %s: got offset %d, want %d", src, off, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "seen.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected no seen.json to be written until MarkSeen/Checkpoint is called, stat err: %v", err)
+	}
+}
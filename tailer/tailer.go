@@ -0,0 +1,319 @@
+// Package tailer streams newly appended lines from one or more log files,
+// tolerating rotation/truncation and resuming from a persisted offset.
+package tailer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Line is a single newly observed line from a tailed source file. Offset is
+// the byte position immediately after this line in the source file; callers
+// should pass it to Advance once the line has been durably handed off, so a
+// restart resumes after it rather than before it (replay) or past it (loss).
+type Line struct {
+	Source string
+	Text   string
+	Offset int64
+}
+
+// OffsetStore is the slice of state.StateStore the tailer needs to resume
+// across restarts; any state.StateStore satisfies it.
+type OffsetStore interface {
+	GetOffset(source string) (int64, error)
+	SetOffset(source string, offset int64) error
+	Checkpoint() error
+}
+
+// Tailer watches every file matching a glob pattern and emits appended lines
+// on a shared channel, one goroutine per matched file.
+type Tailer struct {
+	pattern      string
+	store        OffsetStore
+	pollInterval time.Duration
+
+	lines chan Line
+	errs  chan error
+}
+
+// New creates a Tailer for files matching pattern (a filepath.Glob pattern,
+// e.g. "/var/log/wx/*.log"). store persists and resumes per-file read
+// offsets across restarts; pass a no-op store to always start from 0.
+func New(pattern string, store OffsetStore) (*Tailer, error) {
+	return &Tailer{
+		pattern:      pattern,
+		store:        store,
+		pollInterval: 250 * time.Millisecond,
+		lines:        make(chan Line, 256),
+		errs:         make(chan error, 16),
+	}, nil
+}
+
+// Lines returns the channel on which tailed lines are delivered.
+func (t *Tailer) Lines() <-chan Line { return t.lines }
+
+// Errs returns the channel on which per-file tailing errors are reported.
+func (t *Tailer) Errs() <-chan error { return t.errs }
+
+// Advance records that line has been received off Lines() and handed to the
+// caller's own processing, so a restart resumes after it. Call this once
+// per line actually taken from the channel — a line still sitting in the
+// channel's buffer when the process is killed then has no offset recorded
+// for it and is correctly replayed, instead of being silently skipped
+// because its offset was checkpointed before it was ever consumed.
+func (t *Tailer) Advance(line Line) error {
+	return t.store.SetOffset(line.Source, line.Offset)
+}
+
+// Start resolves the glob pattern and spawns one tailing goroutine per
+// matched file. It returns once all goroutines have been launched; callers
+// should use wg.Wait (or cancel ctx) to know when tailing has stopped.
+func (t *Tailer) Start(ctx interface {
+	Done() <-chan struct{}
+}, wg *sync.WaitGroup) error {
+	matches, err := filepath.Glob(t.pattern)
+	if err != nil {
+		return fmt.Errorf("tailer: invalid pattern %q: %w", t.pattern, err)
+	}
+	if len(matches) == 0 {
+		matches = []string{t.pattern}
+	}
+	for _, path := range matches {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			t.tailFile(ctx, path)
+		}(path)
+	}
+	return nil
+}
+
+// notExistWarnAfter is how many consecutive ENOENT retries tailFile rides
+// out silently before it starts reporting — a rotator's mid-rename window
+// is usually gone within one or two polls, but a typo'd or missing
+// LogFilePath should not look like a silent hang.
+const notExistWarnAfter = 2
+
+func (t *Tailer) tailFile(ctx interface {
+	Done() <-chan struct{}
+}, path string) {
+	var notExistStreak int
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		offset, err := t.store.GetOffset(path)
+		if err != nil {
+			t.sendErr(ctx, fmt.Errorf("tailer: reading offset for %s: %w", path, err))
+		}
+
+		f, inode, err := openAtOffset(path, offset)
+		if err != nil {
+			if os.IsNotExist(err) {
+				// Rotator may be mid-rename; back off and retry. Escalate
+				// once the outage outlasts a brief rename window so a
+				// permanently-wrong path doesn't retry forever in silence.
+				notExistStreak++
+				if notExistStreak == notExistWarnAfter || notExistStreak%(10*notExistWarnAfter) == 0 {
+					t.sendErr(ctx, fmt.Errorf("tailer: %s still missing after %d retries: %w", path, notExistStreak, err))
+				}
+				if sleepOrDone(ctx, t.pollInterval) {
+					return
+				}
+				continue
+			}
+			t.sendErr(ctx, fmt.Errorf("tailer: opening %s: %w", path, err))
+			if sleepOrDone(ctx, t.pollInterval) {
+				return
+			}
+			continue
+		}
+		notExistStreak = 0
+
+		if done := t.readUntilRotated(ctx, path, f, inode, offset); done {
+			f.Close()
+			return
+		}
+		f.Close()
+	}
+}
+
+// readUntilRotated streams lines from f until the file shrinks, is replaced
+// by a new inode, or ctx is cancelled. It returns true once ctx is done.
+// startOffset is f's position on entry, used to compute each emitted Line's
+// Offset without an extra syscall per line.
+func (t *Tailer) readUntilRotated(ctx interface {
+	Done() <-chan struct{}
+}, path string, f *os.File, inode uint64, startOffset int64) bool {
+	watcher, err := fsnotify.NewWatcher()
+	useWatcher := err == nil
+	if useWatcher {
+		defer watcher.Close()
+		if err := watcher.Add(path); err != nil {
+			useWatcher = false
+		}
+	}
+
+	reader := bufio.NewReader(f)
+	pos := startOffset
+	for {
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" && err == nil {
+				pos += int64(len(line))
+				if !t.sendLine(ctx, Line{Source: path, Text: trimNewline(line), Offset: pos}) {
+					return true
+				}
+				continue
+			}
+			if line != "" && err == io.EOF {
+				// Partial line at EOF: rewind so the next read sees it
+				// whole, instead of the half already consumed into line.
+				f.Seek(-int64(len(line)), io.SeekCurrent)
+				break
+			}
+			break
+		}
+
+		offset := int64(-1)
+		if off, err := f.Seek(0, io.SeekCurrent); err == nil {
+			offset = off
+		}
+
+		if rotated, _ := fileRotated(path, inode, offset); rotated {
+			// The new file starts from byte 0 regardless of whatever offset
+			// was last recorded for path, otherwise a rotation caught after
+			// the replacement file has already grown past that offset would
+			// seek into its middle and skip its leading lines.
+			t.setOffset(ctx, path, 0)
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return true
+		case <-watcherEvents(useWatcher, watcher):
+		case <-time.After(t.pollInterval):
+		}
+	}
+}
+
+func watcherEvents(enabled bool, w *fsnotify.Watcher) <-chan fsnotify.Event {
+	if !enabled {
+		return nil
+	}
+	return w.Events
+}
+
+func sleepOrDone(ctx interface{ Done() <-chan struct{} }, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+// sendErr reports err on t.errs, dropping it if ctx is cancelled first so a
+// full buffer during shutdown can't wedge this goroutine forever.
+func (t *Tailer) sendErr(ctx interface{ Done() <-chan struct{} }, err error) {
+	select {
+	case t.errs <- err:
+	case <-ctx.Done():
+	}
+}
+
+// sendLine delivers line on t.lines, reporting whether it was actually
+// sent; it gives up once ctx is cancelled instead of blocking forever on a
+// full buffer during shutdown.
+func (t *Tailer) sendLine(ctx interface{ Done() <-chan struct{} }, line Line) bool {
+	select {
+	case t.lines <- line:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// fileRotated reports whether path now refers to a different inode, or has
+// shrunk below lastOffset (a copytruncate-style rotation: logrotate
+// truncates the file in place, so the inode is unchanged but the content
+// the tailer already read past is gone). Pass a negative lastOffset to skip
+// the size check when the current read position isn't known.
+func fileRotated(path string, lastInode uint64, lastOffset int64) (bool, uint64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return true, 0
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false, lastInode
+	}
+	if stat.Ino != lastInode {
+		return true, stat.Ino
+	}
+	if lastOffset >= 0 && info.Size() < lastOffset {
+		return true, lastInode
+	}
+	return false, lastInode
+}
+
+func openAtOffset(path string, offset int64) (*os.File, uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	inode := uint64(0)
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		inode = stat.Ino
+	}
+	if offset > info.Size() {
+		// File shrank since the offset was recorded; start over.
+		offset = 0
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+	}
+	return f, inode, nil
+}
+
+func trimNewline(s string) string {
+	if n := len(s); n > 0 && s[n-1] == '\n' {
+		s = s[:n-1]
+	}
+	if n := len(s); n > 0 && s[n-1] == '\r' {
+		s = s[:n-1]
+	}
+	return s
+}
+
+func (t *Tailer) setOffset(ctx interface{ Done() <-chan struct{} }, path string, offset int64) {
+	if err := t.store.SetOffset(path, offset); err != nil {
+		t.sendErr(ctx, fmt.Errorf("tailer: recording offset for %s: %w", path, err))
+	}
+}
+
+// Checkpoint flushes the offset store so a restart resumes from the most
+// recently recorded position instead of the start of each file.
+func (t *Tailer) Checkpoint() error {
+	return t.store.Checkpoint()
+}
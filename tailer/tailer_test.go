@@ -0,0 +1,267 @@
+package tailer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore is a minimal in-memory OffsetStore for tests.
+type memStore struct {
+	mu      sync.Mutex
+	offsets map[string]int64
+}
+
+func newMemStore() *memStore {
+	return &memStore{offsets: make(map[string]int64)}
+}
+
+func (m *memStore) GetOffset(source string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.offsets[source], nil
+}
+
+func (m *memStore) SetOffset(source string, offset int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.offsets[source] = offset
+	return nil
+}
+
+func (m *memStore) Checkpoint() error { return nil }
+
+// doneCtx is the minimal interface Start/tailFile accept, satisfied here by
+// a plain channel so the test doesn't need a real context.Context.
+type doneCtx struct{ ch chan struct{} }
+
+func (d doneCtx) Done() <-chan struct{} { return d.ch }
+
+func TestFileRotated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, inode, err := openAtOffset(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rotated, _ := fileRotated(path, inode, 6); rotated {
+		t.Fatal("expected no rotation before the file changes")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new file, more bytes than before\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, newInode := fileRotated(path, inode, 6)
+	if !rotated {
+		t.Fatal("expected rotation to be detected after replacing the file")
+	}
+	if newInode == inode {
+		t.Fatal("expected a different inode after rotation")
+	}
+}
+
+// TestFileRotatedDetectsCopytruncate covers logrotate's copytruncate mode:
+// the file keeps its inode but is truncated in place, so only the size
+// check (not the inode check) can catch it.
+func TestFileRotatedDetectsCopytruncate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("0123456789\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, inode, err := openAtOffset(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rotated, _ := fileRotated(path, inode, 11); rotated {
+		t.Fatal("expected no rotation before the file is truncated")
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("new\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	rotated, sameInode := fileRotated(path, inode, 11)
+	if !rotated {
+		t.Fatal("expected a copytruncate rotation (same inode, smaller size) to be detected")
+	}
+	if sameInode != inode {
+		t.Fatal("expected the inode to be unchanged across a copytruncate rotation")
+	}
+}
+
+// TestTailFileResetsOffsetOnRotation reproduces the scenario where a
+// rotated-in replacement file has already grown past the old file's last
+// recorded offset by the time rotation is noticed: the tailer must still
+// reopen it at 0 rather than seeking into its middle.
+func TestTailFileResetsOffsetOnRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("line-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMemStore()
+	tr, err := New(path, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.pollInterval = 10 * time.Millisecond
+
+	ctx := doneCtx{ch: make(chan struct{})}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tr.tailFile(ctx, path)
+	}()
+
+	mustReadLine(t, tr, "line-1")
+
+	// Pre-seed the offset past what the replacement file will contain yet,
+	// simulating the store recording the old file's final offset right
+	// before rotation is detected.
+	store.SetOffset(path, 1<<20)
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("line-2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mustReadLine(t, tr, "line-2")
+
+	close(ctx.ch)
+	wg.Wait()
+}
+
+// TestTailFileResetsOffsetOnCopytruncate reproduces a logrotate
+// copytruncate rotation - same inode, file truncated to 0 and rewritten -
+// which the inode-only check in fileRotated used to miss entirely, leaving
+// the tailer stuck past the new EOF forever.
+func TestTailFileResetsOffsetOnCopytruncate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("line-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMemStore()
+	tr, err := New(path, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.pollInterval = 10 * time.Millisecond
+
+	ctx := doneCtx{ch: make(chan struct{})}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tr.tailFile(ctx, path)
+	}()
+
+	mustReadLine(t, tr, "line-1")
+
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Give the tailer a chance to notice the truncated (now-shorter) file
+	// before new content lands, matching how logrotate's copytruncate
+	// empties the file before the process resumes writing to it.
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("line-2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mustReadLine(t, tr, "line-2")
+
+	close(ctx.ch)
+	wg.Wait()
+}
+
+func mustReadLine(t *testing.T, tr *Tailer, want string) {
+	t.Helper()
+	select {
+	case line := <-tr.Lines():
+		if line.Text != want {
+			t.Fatalf("got line %q, want %q", line.Text, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for line %q", want)
+	}
+}
+
+// TestTailFileStopsWithoutDrainingOnCancel reproduces a shutdown where the
+// consumer stops reading Lines() before the tailer goroutine has delivered
+// everything it already read off disk: tailFile must still return once ctx
+// is cancelled instead of blocking forever on the full channel buffer.
+func TestTailFileStopsWithoutDrainingOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	// More lines than the Lines() channel's buffer holds, so the tailer
+	// goroutine is guaranteed to still be offering a send when ctx cancels.
+	var content []byte
+	for i := 0; i < 600; i++ {
+		content = append(content, []byte("line\n")...)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newMemStore()
+	tr, err := New(path, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.pollInterval = 10 * time.Millisecond
+
+	ctx := doneCtx{ch: make(chan struct{})}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tr.tailFile(ctx, path)
+	}()
+
+	// Don't drain tr.Lines() at all: once the channel's buffer fills, the
+	// tailer goroutine is stuck offering a send until ctx is cancelled.
+	deadline := time.Now().Add(2 * time.Second)
+	for len(tr.lines) < cap(tr.lines) {
+		if time.Now().After(deadline) {
+			t.Fatal("tailer never filled the Lines() buffer")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(ctx.ch)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tailFile did not return after ctx was cancelled with an undrained channel")
+	}
+}